@@ -1,7 +1,9 @@
 package cloudformation
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"sort"
 	"strings"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/awslabs/goformation/cloudformation/resources"
 
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 )
 
 const (
@@ -19,13 +23,121 @@ const (
 	OutputKeyAPIGatewayWSSEndpoint = "OutputKeyAPIGatewayWSSEndpoint"
 )
 
-func toLogicalName(idx int, parts []string) string {
-	s := strings.Join(parts[:idx+1], "")
-	remove := []string{"{", "}", "+"}
-	for _, char := range remove {
-		s = strings.Replace(s, char, "", -1)
+// RouteConfigAnnotation is the ingress annotation that carries a JSON object
+// mapping an HTTPIngressPath's path to its RouteConfig, allowing per-path
+// overrides of HTTP method, authorization, and CORS behavior.
+const RouteConfigAnnotation = "apigateway.ingress.kubernetes.io/route-config"
+
+// ApiType selects which API Gateway product BuildApiGatewayTemplateFromIngressRule
+// emits. ApiTypeREST (the default, for backwards compatibility with existing
+// TemplateConfig callers) produces the REST API + VPC link template this
+// package has always generated. ApiTypeHTTP produces an API Gateway V2
+// (HTTP API) template against the same load balancer and VPC link, which is
+// billed at the cheaper v2 rate.
+type ApiType string
+
+const (
+	ApiTypeREST ApiType = "REST"
+	ApiTypeHTTP ApiType = "HTTP"
+)
+
+// CORSConfig controls the OPTIONS/MOCK method emitted for a path when CORS
+// support is requested. Any unset field falls back to a permissive default.
+type CORSConfig struct {
+	AllowOrigins []string `json:"allowOrigins,omitempty"`
+	AllowMethods []string `json:"allowMethods,omitempty"`
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+}
+
+// RouteConfig overrides the default ANY/COGNITO_USER_POOLS method generated
+// for an HTTPIngressPath. Methods defaults to ["ANY"] when left unset.
+// Authorization defaults to "COGNITO_USER_POOLS" for REST API templates and
+// to "JWT" for HTTP API (ApiTypeHTTP) templates; either default needs a
+// matching TemplateConfig.Authorizers spec.
+type RouteConfig struct {
+	Methods        []string `json:"methods,omitempty"`
+	Authorization  string   `json:"authorization,omitempty"`
+	AuthorizerName string   `json:"authorizerName,omitempty"`
+	// CORS, when set, emits an OPTIONS/MOCK preflight method responding with
+	// the configured Access-Control-Allow-* headers. It has no effect on HTTP
+	// API (ApiTypeHTTP) templates, which do not yet emit a CORS equivalent.
+	CORS *CORSConfig `json:"cors,omitempty"`
+	// RequestParameters overrides/extends the HTTP API integration's
+	// parameter mapping (see ApiTypeHTTP). It has no effect on REST API
+	// (ApiTypeREST) templates, which always proxy the path verbatim.
+	RequestParameters map[string]string `json:"requestParameters,omitempty"`
+}
+
+// ParseRouteConfigAnnotation decodes the RouteConfigAnnotation value, if
+// present, into a map keyed by ingress path. A missing or empty annotation
+// is not an error - it simply means every path keeps the default behavior.
+func ParseRouteConfigAnnotation(annotations map[string]string) (map[string]RouteConfig, error) {
+	raw, ok := annotations[RouteConfigAnnotation]
+	if !ok || raw == "" {
+		return nil, nil
 	}
-	return s
+
+	routeConfigs := map[string]RouteConfig{}
+	if err := json.Unmarshal([]byte(raw), &routeConfigs); err != nil {
+		return nil, fmt.Errorf("unmarshalling %s annotation: %v", RouteConfigAnnotation, err)
+	}
+
+	return routeConfigs, nil
+}
+
+// sanitizeLogicalName strips s down to the [A-Za-z0-9] alphabet
+// CloudFormation logical IDs are restricted to.
+func sanitizeLogicalName(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// logicalNameRegistry hands out collision-free CloudFormation logical IDs.
+// sanitizeLogicalName alone is lossy - e.g. "/foo-bar" and "/foobar" both
+// sanitize to "foobar" - so two distinct keys that sanitize to the same
+// name are disambiguated with a short deterministic hash suffix instead of
+// silently overwriting one another.
+type logicalNameRegistry struct {
+	claimed map[string]string // sanitized name -> the raw key that claimed it
+}
+
+func newLogicalNameRegistry() *logicalNameRegistry {
+	return &logicalNameRegistry{claimed: map[string]string{}}
+}
+
+// resolve returns the logical name for raw, which is typically a resource
+// prefix (e.g. "Resource" or "Method") concatenated with the un-sanitized,
+// "/"-separated path it was derived from. Calling resolve again with the same raw
+// value returns the same name. It errors if raw's name still collides with
+// another key's after disambiguation, or if the resulting name exceeds
+// CloudFormation's 255 character logical ID limit.
+func (r *logicalNameRegistry) resolve(raw string) (string, error) {
+	name := sanitizeLogicalName(raw)
+
+	if existing, ok := r.claimed[name]; ok && existing != raw {
+		name = fmt.Sprintf("%s%x", name, fnvHash(raw))
+		if existing, ok := r.claimed[name]; ok && existing != raw {
+			return "", fmt.Errorf("logical ID %q collides for both %q and %q", name, existing, raw)
+		}
+	}
+
+	if len(name) > 255 {
+		return "", fmt.Errorf("logical ID %q exceeds CloudFormation's 255 character limit", name)
+	}
+
+	r.claimed[name] = raw
+	return name, nil
+}
+
+func fnvHash(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
 }
 
 func toPath(idx int, parts []string) string {
@@ -35,10 +147,12 @@ func toPath(idx int, parts []string) string {
 	return strings.Join(parts[:idx+1], "/")
 }
 
-func mapApiGatewayMethodsAndResourcesFromPaths(paths []extensionsv1beta1.HTTPIngressPath) map[string]cfn.Resource {
+func mapApiGatewayMethodsAndResourcesFromPaths(paths []extensionsv1beta1.HTTPIngressPath, routeConfigs map[string]RouteConfig, mctx methodBuildContext) (map[string]cfn.Resource, error) {
 	m := map[string]cfn.Resource{}
+	registry := newLogicalNameRegistry()
 
 	for _, path := range paths {
+		routeConfig := routeConfigs[path.Path]
 		parts := strings.Split(path.Path, "/")
 		parts = append(parts, "{proxy+}")
 		for idx, part := range parts {
@@ -47,16 +161,34 @@ func mapApiGatewayMethodsAndResourcesFromPaths(paths []extensionsv1beta1.HTTPIng
 			}
 			ref := cfn.GetAtt("RestAPI", "RootResourceId")
 			if idx > 1 {
-				ref = cfn.Ref(fmt.Sprintf("Resource%s", toLogicalName(idx-1, parts)))
+				parentLogicalName, err := registry.resolve("Resource" + strings.Join(parts[:idx], "/"))
+				if err != nil {
+					return nil, err
+				}
+				ref = cfn.Ref(parentLogicalName)
 			}
 
-			resourceLogicalName := fmt.Sprintf("Resource%s", toLogicalName(idx, parts))
+			resourceLogicalName, err := registry.resolve("Resource" + strings.Join(parts[:idx+1], "/"))
+			if err != nil {
+				return nil, err
+			}
 			m[resourceLogicalName] = buildAWSApiGatewayResource(ref, part)
-			m[fmt.Sprintf("Method%s", toLogicalName(idx, parts))] = buildAWSApiGatewayMethod(resourceLogicalName, toPath(idx, parts))
+
+			methodPrefix, err := registry.resolve("Method" + strings.Join(parts[:idx+1], "/"))
+			if err != nil {
+				return nil, err
+			}
+			methods, err := buildAWSApiGatewayMethods(methodPrefix, resourceLogicalName, toPath(idx, parts), routeConfig, mctx)
+			if err != nil {
+				return nil, err
+			}
+			for methodLogicalName, method := range methods {
+				m[methodLogicalName] = method
+			}
 		}
 	}
 
-	return m
+	return m, nil
 }
 
 func buildAWSApiGatewayResource(ref, part string) *resources.AWSApiGatewayResource {
@@ -85,20 +217,212 @@ func buildAWSApiGatewayWebSocketAPI() *resources.AWSApiGatewayV2Api {
 	}
 }
 
-func buildAWSApiGatewayAuthorizer(CognitoUserPoolArns []string) *resources.AWSApiGatewayAuthorizer {
-	return &resources.AWSApiGatewayAuthorizer{
-		RestApiId:      cfn.Ref("RestAPI"),
-		Name:           "Cognito-Authorizer",
-		Type:           "COGNITO_USER_POOLS",
-		IdentitySource: "method.request.header.Authorization",
-		ProviderARNs:   CognitoUserPoolArns,
+// AuthorizerType selects which concrete Authorizer implementation an
+// AuthorizerSpec builds.
+type AuthorizerType string
+
+const (
+	AuthorizerTypeCognito       AuthorizerType = "COGNITO_USER_POOLS"
+	AuthorizerTypeLambdaToken   AuthorizerType = "TOKEN"
+	AuthorizerTypeLambdaRequest AuthorizerType = "REQUEST"
+	AuthorizerTypeJWT           AuthorizerType = "JWT"
+)
+
+// AuthorizerSpec is the user-facing description of one authorizer to attach
+// to the generated API. Name is the logical identifier RouteConfig.AuthorizerName
+// references from a path's method(s); which other fields apply depends on
+// Type. AuthorizerTypeJWT is only valid when TemplateConfig.ApiType is
+// ApiTypeHTTP - the other three are only valid for ApiTypeREST.
+type AuthorizerSpec struct {
+	Name string
+	Type AuthorizerType
+
+	// Cognito
+	ProviderARNs []string
+
+	// Lambda TOKEN/REQUEST
+	LambdaFunctionArn     string
+	ResultTTLSeconds      int
+	DisableResultCaching  bool
+	LambdaIdentitySource  string   // TOKEN: single header, e.g. "method.request.header.Authorization"
+	LambdaIdentitySources []string // REQUEST: one or more identity sources
+
+	// JWT (HTTP API only)
+	Issuer          string
+	Audience        []string
+	IdentitySources []string
+}
+
+// Authorizer builds the CloudFormation resources for a single authorizer
+// spec: the authorizer resource itself, plus any supporting resources (e.g.
+// the AWSLambdaPermission letting API Gateway invoke a Lambda authorizer).
+type Authorizer interface {
+	// LogicalName is the CloudFormation logical ID other resources use to
+	// reference this authorizer.
+	LogicalName() string
+	// Resources returns the logical-name-to-resource map to merge into the
+	// template.
+	Resources() map[string]cfn.Resource
+}
+
+// CognitoAuthorizer authorizes REST API requests against one or more Cognito
+// user pools.
+type CognitoAuthorizer struct {
+	Name         string
+	ProviderARNs []string
+}
+
+func (a *CognitoAuthorizer) LogicalName() string { return a.Name }
+
+func (a *CognitoAuthorizer) Resources() map[string]cfn.Resource {
+	return map[string]cfn.Resource{
+		a.Name: &resources.AWSApiGatewayAuthorizer{
+			RestApiId:      cfn.Ref("RestAPI"),
+			Name:           a.Name,
+			Type:           string(AuthorizerTypeCognito),
+			IdentitySource: "method.request.header.Authorization",
+			ProviderARNs:   a.ProviderARNs,
+		},
+	}
+}
+
+// lambdaAuthorizer is the shared implementation backing LambdaTokenAuthorizer
+// and LambdaRequestAuthorizer, which differ only in the CFN authorizer Type
+// and the default identity source.
+type lambdaAuthorizer struct {
+	name                 string
+	authorizerType       AuthorizerType
+	functionArn          string
+	identitySource       string
+	resultTTLSeconds     int
+	disableResultCaching bool
+}
+
+func (a *lambdaAuthorizer) LogicalName() string { return a.name }
+
+func (a *lambdaAuthorizer) Resources() map[string]cfn.Resource {
+	ttl := a.resultTTLSeconds
+	if a.disableResultCaching {
+		ttl = 0
+	}
+
+	return map[string]cfn.Resource{
+		a.name: &resources.AWSApiGatewayAuthorizer{
+			RestApiId:                    cfn.Ref("RestAPI"),
+			Name:                         a.name,
+			Type:                         string(a.authorizerType),
+			AuthorizerUri:                cfn.Join("", []string{"arn:aws:apigateway:", cfn.Ref("AWS::Region"), ":lambda:path/2015-03-31/functions/", a.functionArn, "/invocations"}),
+			IdentitySource:               a.identitySource,
+			AuthorizerResultTtlInSeconds: ttl,
+		},
+		fmt.Sprintf("%sPermission", a.name): &resources.AWSLambdaPermission{
+			Action:       "lambda:InvokeFunction",
+			FunctionName: a.functionArn,
+			Principal:    "apigateway.amazonaws.com",
+			SourceArn:    cfn.Join("", []string{"arn:aws:execute-api:", cfn.Ref("AWS::Region"), ":", cfn.Ref("AWS::AccountId"), ":", cfn.Ref("RestAPI"), "/authorizers/", cfn.Ref(a.name)}),
+		},
+	}
+}
+
+// LambdaTokenAuthorizer authorizes REST API requests by invoking a Lambda
+// function with a single bearer-token identity source (CFN type "TOKEN").
+type LambdaTokenAuthorizer struct {
+	lambdaAuthorizer
+}
+
+// NewLambdaTokenAuthorizer builds a LambdaTokenAuthorizer from spec, defaulting
+// LambdaIdentitySource to the Authorization header when unset.
+func NewLambdaTokenAuthorizer(spec AuthorizerSpec) *LambdaTokenAuthorizer {
+	identitySource := spec.LambdaIdentitySource
+	if identitySource == "" {
+		identitySource = "method.request.header.Authorization"
+	}
+
+	return &LambdaTokenAuthorizer{lambdaAuthorizer{
+		name:                 spec.Name,
+		authorizerType:       AuthorizerTypeLambdaToken,
+		functionArn:          spec.LambdaFunctionArn,
+		identitySource:       identitySource,
+		resultTTLSeconds:     spec.ResultTTLSeconds,
+		disableResultCaching: spec.DisableResultCaching,
+	}}
+}
+
+// LambdaRequestAuthorizer authorizes REST API requests by invoking a Lambda
+// function with one or more request-based identity sources (CFN type
+// "REQUEST").
+type LambdaRequestAuthorizer struct {
+	lambdaAuthorizer
+}
+
+// NewLambdaRequestAuthorizer builds a LambdaRequestAuthorizer from spec,
+// defaulting LambdaIdentitySources to the Authorization header when unset.
+func NewLambdaRequestAuthorizer(spec AuthorizerSpec) *LambdaRequestAuthorizer {
+	identitySources := spec.LambdaIdentitySources
+	if len(identitySources) == 0 {
+		identitySources = []string{"method.request.header.Authorization"}
 	}
+
+	return &LambdaRequestAuthorizer{lambdaAuthorizer{
+		name:                 spec.Name,
+		authorizerType:       AuthorizerTypeLambdaRequest,
+		functionArn:          spec.LambdaFunctionArn,
+		identitySource:       strings.Join(identitySources, ","),
+		resultTTLSeconds:     spec.ResultTTLSeconds,
+		disableResultCaching: spec.DisableResultCaching,
+	}}
+}
+
+// JWTAuthorizer authorizes HTTP API (ApiTypeHTTP) requests against an OIDC
+// issuer, using an AWSApiGatewayV2Authorizer.
+type JWTAuthorizer struct {
+	Name            string
+	Issuer          string
+	Audience        []string
+	IdentitySources []string
 }
 
-func buildAWSApiGatewayDeployment(stageName string, dependsOn []string) *resources.AWSApiGatewayDeployment {
+func (a *JWTAuthorizer) LogicalName() string { return a.Name }
+
+func (a *JWTAuthorizer) Resources() map[string]cfn.Resource {
+	identitySources := a.IdentitySources
+	if len(identitySources) == 0 {
+		identitySources = []string{"$request.header.Authorization"}
+	}
+
+	return map[string]cfn.Resource{
+		a.Name: &resources.AWSApiGatewayV2Authorizer{
+			ApiId:          cfn.Ref("HttpApi"),
+			AuthorizerType: string(AuthorizerTypeJWT),
+			Name:           a.Name,
+			IdentitySource: identitySources,
+			JwtConfiguration: &resources.AWSApiGatewayV2Authorizer_JWTConfiguration{
+				Issuer:   a.Issuer,
+				Audience: a.Audience,
+			},
+		},
+	}
+}
+
+// buildAuthorizer constructs the Authorizer implementation matching spec.Type.
+func buildAuthorizer(spec AuthorizerSpec) (Authorizer, error) {
+	switch spec.Type {
+	case AuthorizerTypeCognito:
+		return &CognitoAuthorizer{Name: spec.Name, ProviderARNs: spec.ProviderARNs}, nil
+	case AuthorizerTypeLambdaToken:
+		return NewLambdaTokenAuthorizer(spec), nil
+	case AuthorizerTypeLambdaRequest:
+		return NewLambdaRequestAuthorizer(spec), nil
+	case AuthorizerTypeJWT:
+		return &JWTAuthorizer{Name: spec.Name, Issuer: spec.Issuer, Audience: spec.Audience, IdentitySources: spec.IdentitySources}, nil
+	default:
+		return nil, fmt.Errorf("unsupported authorizer type %q for authorizer %q", spec.Type, spec.Name)
+	}
+}
+
+func buildAWSApiGatewayDeployment(dependsOn []string) *resources.AWSApiGatewayDeployment {
 	d := &resources.AWSApiGatewayDeployment{
 		RestApiId: cfn.Ref("RestAPI"),
-		StageName: stageName,
 	}
 
 	// Since we construct a map of in `mapApiGatewayMethodsAndResourcesFromPaths` we can't determine the order
@@ -111,8 +435,118 @@ func buildAWSApiGatewayDeployment(stageName string, dependsOn []string) *resourc
 	return d
 }
 
-func buildAWSElasticLoadBalancingV2Listener() *resources.AWSElasticLoadBalancingV2Listener {
-	return &resources.AWSElasticLoadBalancingV2Listener{
+// defaultAccessLogFormat is a JSON access log line covering the fields most
+// operators want out of the box: who called what, when, and how it went.
+const defaultAccessLogFormat = `{"requestId":"$context.requestId","ip":"$context.identity.sourceIp","caller":"$context.identity.caller","user":"$context.identity.user","requestTime":"$context.requestTime","httpMethod":"$context.httpMethod","resourcePath":"$context.resourcePath","status":"$context.status","protocol":"$context.protocol","responseLength":"$context.responseLength"}`
+
+// AccessLoggingConfig turns on REST API stage access logging. Format
+// defaults to defaultAccessLogFormat and RetentionInDays to 30 when left
+// unset (zero value).
+type AccessLoggingConfig struct {
+	Format          string
+	RetentionInDays int
+}
+
+func buildAWSLogsLogGroup(retentionInDays int) *resources.AWSLogsLogGroup {
+	if retentionInDays == 0 {
+		retentionInDays = 30
+	}
+
+	return &resources.AWSLogsLogGroup{
+		RetentionInDays: retentionInDays,
+	}
+}
+
+// buildAWSApiGatewayStage builds the REST API's deployment stage as its own
+// resource (rather than via AWSApiGatewayDeployment.StageName) so that it can
+// carry an AccessLogSetting, mirroring the pattern the WebSocket API already
+// uses for its stage/deployment split.
+func buildAWSApiGatewayStage(stageName string, accessLogging *AccessLoggingConfig) *resources.AWSApiGatewayStage {
+	s := &resources.AWSApiGatewayStage{
+		RestApiId:    cfn.Ref("RestAPI"),
+		DeploymentId: cfn.Ref("Deployment"),
+		StageName:    stageName,
+	}
+
+	if accessLogging != nil {
+		format := accessLogging.Format
+		if format == "" {
+			format = defaultAccessLogFormat
+		}
+		s.AccessLogSetting = &resources.AWSApiGatewayStage_AccessLogSetting{
+			DestinationArn: cfn.GetAtt("AccessLogGroup", "Arn"),
+			Format:         format,
+		}
+	}
+
+	return s
+}
+
+// buildAWSWAFv2WebACLAssociation associates webACLArn with the REST API
+// stage, identified by its CloudFormation stage ARN. It depends on the Stage
+// resource explicitly since the ARN only references the stage by name, which
+// gives CloudFormation no implicit ordering constraint of its own.
+func buildAWSWAFv2WebACLAssociation(stageName, webACLArn string) *resources.AWSWAFv2WebACLAssociation {
+	a := &resources.AWSWAFv2WebACLAssociation{
+		ResourceArn: cfn.Join("", []string{"arn:aws:apigateway:", cfn.Ref("AWS::Region"), "::/restapis/", cfn.Ref("RestAPI"), "/stages/", stageName}),
+		WebACLArn:   webACLArn,
+	}
+	a.SetDependsOn([]string{"Stage"})
+	return a
+}
+
+// UsagePlanConfig provisions an API key and usage plan against the REST
+// API's stage, enforcing the given quota and throttle limits.
+type UsagePlanConfig struct {
+	QuotaLimit         int
+	QuotaPeriod        string // DAY, WEEK, or MONTH
+	ThrottleBurstLimit int
+	ThrottleRateLimit  float64
+}
+
+// buildAWSApiGatewayUsagePlan depends on the Stage resource explicitly since
+// ApiStages only references the stage by name, which gives CloudFormation no
+// implicit ordering constraint of its own.
+func buildAWSApiGatewayUsagePlan(stageName string, cfg *UsagePlanConfig) *resources.AWSApiGatewayUsagePlan {
+	p := &resources.AWSApiGatewayUsagePlan{
+		ApiStages: []resources.AWSApiGatewayUsagePlan_ApiStage{
+			{
+				ApiId: cfn.Ref("RestAPI"),
+				Stage: stageName,
+			},
+		},
+		Quota: &resources.AWSApiGatewayUsagePlan_QuotaSettings{
+			Limit:  cfg.QuotaLimit,
+			Period: cfg.QuotaPeriod,
+		},
+		Throttle: &resources.AWSApiGatewayUsagePlan_ThrottleSettings{
+			BurstLimit: cfg.ThrottleBurstLimit,
+			RateLimit:  cfg.ThrottleRateLimit,
+		},
+	}
+	p.SetDependsOn([]string{"Stage"})
+	return p
+}
+
+func buildAWSApiGatewayApiKey() *resources.AWSApiGatewayApiKey {
+	return &resources.AWSApiGatewayApiKey{
+		Enabled: true,
+	}
+}
+
+func buildAWSApiGatewayUsagePlanKey() *resources.AWSApiGatewayUsagePlanKey {
+	return &resources.AWSApiGatewayUsagePlanKey{
+		KeyId:       cfn.Ref("ApiKey"),
+		KeyType:     "API_KEY",
+		UsagePlanId: cfn.Ref("UsagePlan"),
+	}
+}
+
+// buildAWSElasticLoadBalancingV2Listener builds the NLB listener. When
+// backendCertificateArn is non-empty it listens with TLS on 443 instead of
+// plain TCP on 80, terminating TLS at the NLB using the supplied ACM cert.
+func buildAWSElasticLoadBalancingV2Listener(backendCertificateArn string) *resources.AWSElasticLoadBalancingV2Listener {
+	l := &resources.AWSElasticLoadBalancingV2Listener{
 		LoadBalancerArn: cfn.Ref("LoadBalancer"),
 		Protocol:        "TCP",
 		Port:            80,
@@ -123,6 +557,17 @@ func buildAWSElasticLoadBalancingV2Listener() *resources.AWSElasticLoadBalancing
 			},
 		},
 	}
+
+	if backendCertificateArn != "" {
+		l.Protocol = "TLS"
+		l.Port = 443
+		l.SslPolicy = "ELBSecurityPolicy-TLS13-1-2-2021-06"
+		l.Certificates = []resources.AWSElasticLoadBalancingV2Listener_Certificate{
+			{CertificateArn: backendCertificateArn},
+		}
+	}
+
+	return l
 }
 
 func buildAWSElasticLoadBalancingV2LoadBalancer(subnetIDs []string) *resources.AWSElasticLoadBalancingV2LoadBalancer {
@@ -140,12 +585,17 @@ func buildAWSElasticLoadBalancingV2LoadBalancer(subnetIDs []string) *resources.A
 	}
 }
 
-func buildAWSElasticLoadBalancingV2TargetGroup(vpcID string, instanceIDs []string, nodePort int, dependsOn []string) *resources.AWSElasticLoadBalancingV2TargetGroup {
+func buildAWSElasticLoadBalancingV2TargetGroup(vpcID string, instanceIDs []string, nodePort int, backendCertificateArn string, dependsOn []string) *resources.AWSElasticLoadBalancingV2TargetGroup {
 	targets := make([]resources.AWSElasticLoadBalancingV2TargetGroup_TargetDescription, len(instanceIDs))
 	for i, instanceID := range instanceIDs {
 		targets[i] = resources.AWSElasticLoadBalancingV2TargetGroup_TargetDescription{Id: instanceID}
 	}
 
+	protocol := "TCP"
+	if backendCertificateArn != "" {
+		protocol = "TLS"
+	}
+
 	return &resources.AWSElasticLoadBalancingV2TargetGroup{
 		HealthCheckIntervalSeconds: 30,
 		HealthCheckPort:            "traffic-port",
@@ -153,7 +603,7 @@ func buildAWSElasticLoadBalancingV2TargetGroup(vpcID string, instanceIDs []strin
 		HealthCheckTimeoutSeconds:  10,
 		HealthyThresholdCount:      3,
 		Port:                       nodePort,
-		Protocol:                   "TCP",
+		Protocol:                   protocol,
 		Tags: []resources.Tag{
 			{
 				Key:   "com.github.amazon-apigateway-ingress-controller/stack",
@@ -179,14 +629,68 @@ func buildAWSApiGatewayVpcLink(dependsOn []string) *resources.AWSApiGatewayVpcLi
 	return r
 }
 
-func buildAWSApiGatewayMethod(resourceLogicalName, path string) *resources.AWSApiGatewayMethod {
+// buildAWSApiGatewayMethods returns one AWSApiGatewayMethod per HTTP method
+// configured on routeConfig (ANY/COGNITO_USER_POOLS when routeConfig is the
+// zero value), plus an additional CORS preflight method when routeConfig.CORS
+// is set. Keys are logical names derived from methodPrefix. It errors if an
+// explicit method named "OPTIONS" would collide with the CORS preflight key,
+// or if a method's resolved AuthorizationType requires an authorizer that
+// doesn't resolve (see buildAWSApiGatewayMethod).
+func buildAWSApiGatewayMethods(methodPrefix, resourceLogicalName, path string, routeConfig RouteConfig, mctx methodBuildContext) (map[string]*resources.AWSApiGatewayMethod, error) {
+	httpMethods := routeConfig.Methods
+	usingDefaultMethods := len(httpMethods) == 0
+	if usingDefaultMethods {
+		httpMethods = []string{"ANY"}
+	}
+
+	authorizationType := routeConfig.Authorization
+	if authorizationType == "" {
+		authorizationType = string(AuthorizerTypeCognito)
+	}
+
+	m := map[string]*resources.AWSApiGatewayMethod{}
+	for _, httpMethod := range httpMethods {
+		logicalName := methodPrefix
+		if !usingDefaultMethods {
+			logicalName = fmt.Sprintf("%s%s", methodPrefix, strings.Title(strings.ToLower(httpMethod)))
+		}
+		method, err := buildAWSApiGatewayMethod(resourceLogicalName, path, httpMethod, authorizationType, routeConfig.AuthorizerName, mctx)
+		if err != nil {
+			return nil, err
+		}
+		m[logicalName] = method
+	}
+
+	if routeConfig.CORS != nil {
+		corsLogicalName := fmt.Sprintf("%sOptions", methodPrefix)
+		if _, exists := m[corsLogicalName]; exists {
+			return nil, fmt.Errorf("path %q declares both an explicit OPTIONS method and CORS, which both resolve to logical name %q", path, corsLogicalName)
+		}
+		m[corsLogicalName] = buildAWSApiGatewayCORSMethod(resourceLogicalName, routeConfig.CORS)
+	}
+
+	return m, nil
+}
+
+// authorizationTypeRequiresAuthorizer reports whether t is a method
+// AuthorizationType that is only valid alongside a resolved AuthorizerId -
+// AWS_IAM and NONE need no authorizer resource.
+func authorizationTypeRequiresAuthorizer(t string) bool {
+	switch t {
+	case string(AuthorizerTypeCognito), "CUSTOM", string(AuthorizerTypeJWT):
+		return true
+	default:
+		return false
+	}
+}
+
+func buildAWSApiGatewayMethod(resourceLogicalName, path, httpMethod, authorizationType, authorizerName string, mctx methodBuildContext) (*resources.AWSApiGatewayMethod, error) {
 	m := &resources.AWSApiGatewayMethod{
 		RequestParameters: map[string]bool{
 			"method.request.path.proxy": true,
 		},
-		AuthorizationType: "COGNITO_USER_POOLS",
-		HttpMethod:        "ANY",
-		AuthorizerId:      cfn.Ref("CognitoAuthorizer"),
+		AuthorizationType: authorizationType,
+		HttpMethod:        httpMethod,
 		ResourceId:        cfn.Ref(resourceLogicalName),
 		RestApiId:         cfn.Ref("RestAPI"),
 		Integration: &resources.AWSApiGatewayMethod_Integration{
@@ -200,12 +704,80 @@ func buildAWSApiGatewayMethod(resourceLogicalName, path string) *resources.AWSAp
 			},
 			Type:            "HTTP_PROXY",
 			TimeoutInMillis: 29000,
-			Uri:             cfn.Join("", []string{"http://", cfn.GetAtt("LoadBalancer", "DNSName"), path}),
+			Uri:             cfn.Join("", []string{mctx.backendBaseURI, path}),
 		},
 	}
 
-	m.SetDependsOn([]string{"LoadBalancer", "CognitoAuthorizer"})
-	return m
+	dependsOn := []string{"LoadBalancer"}
+	if authorizationType != "NONE" {
+		authorizerLogicalName := authorizerName
+		if authorizerLogicalName == "" {
+			authorizerLogicalName = mctx.defaultAuthorizerNames[authorizationType]
+		}
+		if authorizerLogicalName != "" {
+			m.AuthorizerId = cfn.Ref(authorizerLogicalName)
+			dependsOn = append(dependsOn, authorizerLogicalName)
+		} else if authorizationTypeRequiresAuthorizer(authorizationType) {
+			return nil, fmt.Errorf("%s %s: authorization type %q requires an authorizer, but none was configured (add an AuthorizerSpec of the matching type or set RouteConfig.AuthorizerName)", httpMethod, path, authorizationType)
+		}
+	}
+
+	m.SetDependsOn(dependsOn)
+	return m, nil
+}
+
+// buildAWSApiGatewayCORSMethod builds the OPTIONS/MOCK method CloudFormation
+// expects for CORS preflight requests, responding with the configured
+// Access-Control-Allow-* headers without reaching the backend.
+func buildAWSApiGatewayCORSMethod(resourceLogicalName string, cors *CORSConfig) *resources.AWSApiGatewayMethod {
+	allowOrigins := "*"
+	if len(cors.AllowOrigins) > 0 {
+		allowOrigins = strings.Join(cors.AllowOrigins, ",")
+	}
+
+	allowMethods := "GET,POST,PUT,PATCH,DELETE,OPTIONS"
+	if len(cors.AllowMethods) > 0 {
+		allowMethods = strings.Join(cors.AllowMethods, ",")
+	}
+
+	allowHeaders := "Content-Type,Authorization"
+	if len(cors.AllowHeaders) > 0 {
+		allowHeaders = strings.Join(cors.AllowHeaders, ",")
+	}
+
+	return &resources.AWSApiGatewayMethod{
+		AuthorizationType: "NONE",
+		HttpMethod:        "OPTIONS",
+		ResourceId:        cfn.Ref(resourceLogicalName),
+		RestApiId:         cfn.Ref("RestAPI"),
+		MethodResponses: []resources.AWSApiGatewayMethod_MethodResponse{
+			{
+				StatusCode: "200",
+				ResponseParameters: map[string]bool{
+					"method.response.header.Access-Control-Allow-Headers": true,
+					"method.response.header.Access-Control-Allow-Methods": true,
+					"method.response.header.Access-Control-Allow-Origin":  true,
+				},
+			},
+		},
+		Integration: &resources.AWSApiGatewayMethod_Integration{
+			Type:                "MOCK",
+			PassthroughBehavior: "WHEN_NO_MATCH",
+			RequestTemplates: map[string]string{
+				"application/json": "{\"statusCode\": 200}",
+			},
+			IntegrationResponses: []resources.AWSApiGatewayMethod_IntegrationResponse{
+				{
+					StatusCode: "200",
+					ResponseParameters: map[string]string{
+						"method.response.header.Access-Control-Allow-Headers": fmt.Sprintf("'%s'", allowHeaders),
+						"method.response.header.Access-Control-Allow-Methods": fmt.Sprintf("'%s'", allowMethods),
+						"method.response.header.Access-Control-Allow-Origin":  fmt.Sprintf("'%s'", allowOrigins),
+					},
+				},
+			},
+		},
+	}
 }
 
 func buildAWSEC2SecurityGroupIngresses(securityGroupIds []string, cidr string, nodePort int) []*resources.AWSEC2SecurityGroupIngress {
@@ -286,22 +858,124 @@ func buildAWSAPIGatewayWSSStage(stageName string) *resources.AWSApiGatewayV2Stag
 }
 
 type TemplateConfig struct {
-	Network             *network.Network
-	Rule                extensionsv1beta1.IngressRule
-	NodePort            int
-	StageName           string
-	Arns                []string
-	CognitoUserPoolArns []string
-	CustomDomainName    string
-	CertificateArn      string
+	Network          *network.Network
+	Rule             extensionsv1beta1.IngressRule
+	NodePort         int
+	StageName        string
+	Arns             []string
+	CustomDomainName string
+	CertificateArn   string
+	RouteConfigs     map[string]RouteConfig
+	// BackendCertificateArn is the ACM certificate used to terminate TLS on
+	// the internal NLB listener. When set, the listener switches from
+	// TCP:80 to TLS:443 and the VPC-link integration URI is rewritten to
+	// https://. BackendSNIHostname, if set alongside BackendCertificateArn,
+	// is used as the integration host in place of the load balancer's DNS
+	// name (it is ignored when BackendCertificateArn is empty) - useful when the
+	// backend expects a specific SNI hostname.
+	BackendCertificateArn string
+	BackendSNIHostname    string
+	// ApiType selects REST (default) vs. HTTP API generation. See ApiType.
+	ApiType ApiType
+	// Authorizers are the authorizers available to reference by name from
+	// RouteConfig.AuthorizerName. AuthorizerTypeCognito/LambdaToken/LambdaRequest
+	// specs apply to ApiTypeREST; AuthorizerTypeJWT specs apply to ApiTypeHTTP.
+	// When a path's RouteConfig.AuthorizerName is empty, the first spec whose
+	// Type matches the path's (possibly defaulted) Authorization is used;
+	// LambdaToken and LambdaRequest specs match Authorization "CUSTOM".
+	Authorizers []AuthorizerSpec
+	// AccessLogging, when non-nil, emits a log group and attaches it to the
+	// REST API stage. ApiTypeREST only.
+	AccessLogging *AccessLoggingConfig
+	// WebACLArn, when set, associates the named WAFv2 web ACL with the REST
+	// API stage. ApiTypeREST only.
+	WebACLArn string
+	// UsagePlan, when non-nil, provisions an API key and usage plan enforcing
+	// its quota/throttle settings against the REST API stage. ApiTypeREST only.
+	UsagePlan *UsagePlanConfig
+}
+
+// methodBuildContext carries the per-template inputs threaded down to every
+// method/route/integration builder, avoiding a long, ever-growing parameter
+// list as those builders pick up more cross-cutting inputs.
+type methodBuildContext struct {
+	backendBaseURI         string
+	defaultAuthorizerNames map[string]string
+}
+
+// methodAuthorizationType returns the AWSApiGatewayMethod.AuthorizationType
+// value a spec of type t is referenced by. Cognito and JWT authorizers are
+// keyed by their own name; both Lambda authorizer kinds (TOKEN and REQUEST)
+// are only ever valid on a method as AuthorizationType "CUSTOM", so they
+// collapse to the same key here.
+func methodAuthorizationType(t AuthorizerType) string {
+	switch t {
+	case AuthorizerTypeLambdaToken, AuthorizerTypeLambdaRequest:
+		return "CUSTOM"
+	default:
+		return string(t)
+	}
+}
+
+// defaultAuthorizerLogicalNames maps each method-facing AuthorizationType
+// present in specs (see methodAuthorizationType) to the logical name of its
+// first spec, used when a path's RouteConfig leaves AuthorizerName unset.
+func defaultAuthorizerLogicalNames(specs []AuthorizerSpec) map[string]string {
+	m := map[string]string{}
+	for _, spec := range specs {
+		key := methodAuthorizationType(spec.Type)
+		if _, ok := m[key]; !ok {
+			m[key] = spec.Name
+		}
+	}
+	return m
+}
+
+// buildBackendBaseURI returns the scheme+host CFN intrinsic used as the
+// prefix for every VPC-link integration URI. It switches to https:// against
+// BackendSNIHostname (when set) or the load balancer's DNS name when a
+// backend certificate is configured, and otherwise keeps the existing
+// http://<LoadBalancer DNS name> behavior.
+func buildBackendBaseURI(backendCertificateArn, backendSNIHostname string) string {
+	if backendCertificateArn == "" {
+		return cfn.Join("", []string{"http://", cfn.GetAtt("LoadBalancer", "DNSName")})
+	}
+
+	host := backendSNIHostname
+	if host == "" {
+		host = cfn.GetAtt("LoadBalancer", "DNSName")
+	}
+	return cfn.Join("", []string{"https://", host})
 }
 
-func BuildApiGatewayTemplateFromIngressRule(cfg *TemplateConfig) *cfn.Template {
+func BuildApiGatewayTemplateFromIngressRule(cfg *TemplateConfig) (*cfn.Template, error) {
+	if cfg.ApiType == ApiTypeHTTP {
+		return buildHTTPApiGatewayTemplate(cfg)
+	}
+
 	template := cfn.NewTemplate()
 	paths := cfg.Rule.IngressRuleValue.HTTP.Paths
 
+	mctx := methodBuildContext{
+		backendBaseURI:         buildBackendBaseURI(cfg.BackendCertificateArn, cfg.BackendSNIHostname),
+		defaultAuthorizerNames: defaultAuthorizerLogicalNames(cfg.Authorizers),
+	}
+
+	for _, spec := range cfg.Authorizers {
+		authorizer, err := buildAuthorizer(spec)
+		if err != nil {
+			return nil, err
+		}
+		for k, resource := range authorizer.Resources() {
+			template.Resources[k] = resource
+		}
+	}
+
 	methodLogicalNames := []string{}
-	resourceMap := mapApiGatewayMethodsAndResourcesFromPaths(paths)
+	resourceMap, err := mapApiGatewayMethodsAndResourcesFromPaths(paths, cfg.RouteConfigs, mctx)
+	if err != nil {
+		return nil, err
+	}
 	for k, resource := range resourceMap {
 		if _, ok := resource.(*resources.AWSApiGatewayMethod); ok {
 			methodLogicalNames = append(methodLogicalNames, k)
@@ -310,10 +984,10 @@ func BuildApiGatewayTemplateFromIngressRule(cfg *TemplateConfig) *cfn.Template {
 		template.Resources[k] = resource
 	}
 
-	targetGroup := buildAWSElasticLoadBalancingV2TargetGroup(*cfg.Network.Vpc.VpcId, cfg.Network.InstanceIDs, cfg.NodePort, []string{"LoadBalancer"})
+	targetGroup := buildAWSElasticLoadBalancingV2TargetGroup(*cfg.Network.Vpc.VpcId, cfg.Network.InstanceIDs, cfg.NodePort, cfg.BackendCertificateArn, []string{"LoadBalancer"})
 	template.Resources["TargetGroup"] = targetGroup
 
-	listener := buildAWSElasticLoadBalancingV2Listener()
+	listener := buildAWSElasticLoadBalancingV2Listener(cfg.BackendCertificateArn)
 	template.Resources["Listener"] = listener
 
 	securityGroupIngresses := buildAWSEC2SecurityGroupIngresses(cfg.Network.SecurityGroupIDs, *cfg.Network.Vpc.CidrBlock, cfg.NodePort)
@@ -342,12 +1016,27 @@ func BuildApiGatewayTemplateFromIngressRule(cfg *TemplateConfig) *cfn.Template {
 	webSocketStage := buildAWSAPIGatewayWSSStage(cfg.StageName)
 	template.Resources["webSocketStage"] = webSocketStage
 
-	cognitoAuthorizer := buildAWSApiGatewayAuthorizer(cfg.CognitoUserPoolArns)
-	template.Resources["CognitoAuthorizer"] = cognitoAuthorizer
-
-	deployment := buildAWSApiGatewayDeployment(cfg.StageName, methodLogicalNames)
+	deployment := buildAWSApiGatewayDeployment(methodLogicalNames)
 	template.Resources["Deployment"] = deployment
 
+	if cfg.AccessLogging != nil {
+		retentionInDays := cfg.AccessLogging.RetentionInDays
+		template.Resources["AccessLogGroup"] = buildAWSLogsLogGroup(retentionInDays)
+	}
+
+	stage := buildAWSApiGatewayStage(cfg.StageName, cfg.AccessLogging)
+	template.Resources["Stage"] = stage
+
+	if cfg.WebACLArn != "" {
+		template.Resources["WebACLAssociation"] = buildAWSWAFv2WebACLAssociation(cfg.StageName, cfg.WebACLArn)
+	}
+
+	if cfg.UsagePlan != nil {
+		template.Resources["UsagePlan"] = buildAWSApiGatewayUsagePlan(cfg.StageName, cfg.UsagePlan)
+		template.Resources["ApiKey"] = buildAWSApiGatewayApiKey()
+		template.Resources["UsagePlanKey"] = buildAWSApiGatewayUsagePlanKey()
+	}
+
 	loadBalancer := buildAWSElasticLoadBalancingV2LoadBalancer(cfg.Network.SubnetIDs)
 	template.Resources["LoadBalancer"] = loadBalancer
 
@@ -366,5 +1055,329 @@ func BuildApiGatewayTemplateFromIngressRule(cfg *TemplateConfig) *cfn.Template {
 		OutputKeyAPIGatewayWSSEndpoint: Output{Value: cfn.Join("", []string{"wss://", cfn.Ref("webSocketAPI"), ".execute-api.", cfn.Ref("AWS::Region"), ".amazonaws.com/", cfg.StageName})},
 	}
 
-	return template
+	return template, nil
+}
+
+func buildAWSApiGatewayV2HttpApi() *resources.AWSApiGatewayV2Api {
+	return &resources.AWSApiGatewayV2Api{
+		Name:         cfn.Ref("AWS::StackName"),
+		ProtocolType: "HTTP",
+	}
+}
+
+// toV2Path rewrites a REST-style ingress path into the path portion of an
+// API Gateway V2 route key, appending a "{proxy+}" catch-all the same way
+// the REST template always falls through to a {proxy+} resource.
+func toV2Path(path string) string {
+	trimmed := strings.TrimRight(path, "/")
+	if trimmed == "" {
+		return "/{proxy+}"
+	}
+	return trimmed + "/{proxy+}"
+}
+
+func toV2RouteKey(httpMethod, path string) string {
+	return fmt.Sprintf("%s %s", httpMethod, toV2Path(path))
+}
+
+// buildAWSApiGatewayV2Integration builds the HTTP_PROXY/VPC_LINK integration
+// backing a single HTTP API route. The proxy path parameter is always
+// forwarded via the "overwrite:path" request parameter; routeConfig.RequestParameters
+// can add or override additional mappings.
+func buildAWSApiGatewayV2Integration(backendBaseURI, path string, requestParameters map[string]string) *resources.AWSApiGatewayV2Integration {
+	params := map[string]string{
+		"overwrite:path": "$request.path.proxy",
+	}
+	for k, v := range requestParameters {
+		params[k] = v
+	}
+
+	return &resources.AWSApiGatewayV2Integration{
+		ApiId:                cfn.Ref("HttpApi"),
+		ConnectionId:         cfn.Ref("VPCLink"),
+		ConnectionType:       "VPC_LINK",
+		IntegrationMethod:    "ANY",
+		IntegrationType:      "HTTP_PROXY",
+		IntegrationUri:       cfn.Join("", []string{backendBaseURI, toV2Path(path)}),
+		PayloadFormatVersion: "1.0",
+		RequestParameters:    params,
+		TimeoutInMillis:      29000,
+	}
+}
+
+func buildAWSApiGatewayV2RouteResource(routeKey, integrationLogicalName, authorizationType, authorizerLogicalName string) *resources.AWSApiGatewayV2Route {
+	r := &resources.AWSApiGatewayV2Route{
+		ApiId:             cfn.Ref("HttpApi"),
+		RouteKey:          routeKey,
+		AuthorizationType: authorizationType,
+		Target:            cfn.Join("/", []string{"integrations", cfn.Ref(integrationLogicalName)}),
+	}
+
+	if authorizerLogicalName != "" {
+		r.AuthorizerId = cfn.Ref(authorizerLogicalName)
+	}
+
+	return r
+}
+
+func buildAWSApiGatewayV2HttpStage(stageName string) *resources.AWSApiGatewayV2Stage {
+	return &resources.AWSApiGatewayV2Stage{
+		ApiId:      cfn.Ref("HttpApi"),
+		StageName:  stageName,
+		AutoDeploy: true,
+	}
+}
+
+// buildHTTPApiGatewayTemplate is the ApiTypeHTTP counterpart to
+// BuildApiGatewayTemplateFromIngressRule's default REST API template. It
+// reuses the same load balancer, target group, listener, and VPC link, but
+// fronts them with an API Gateway V2 (HTTP API) instead of a REST API.
+func buildHTTPApiGatewayTemplate(cfg *TemplateConfig) (*cfn.Template, error) {
+	template := cfn.NewTemplate()
+	paths := cfg.Rule.IngressRuleValue.HTTP.Paths
+	registry := newLogicalNameRegistry()
+
+	backendBaseURI := buildBackendBaseURI(cfg.BackendCertificateArn, cfg.BackendSNIHostname)
+
+	template.Resources["HttpApi"] = buildAWSApiGatewayV2HttpApi()
+
+	defaultAuthorizerNames := map[AuthorizerType]string{}
+	for _, spec := range cfg.Authorizers {
+		if spec.Type != AuthorizerTypeJWT {
+			continue
+		}
+		authorizer, err := buildAuthorizer(spec)
+		if err != nil {
+			return nil, err
+		}
+		for k, resource := range authorizer.Resources() {
+			template.Resources[k] = resource
+		}
+		if _, ok := defaultAuthorizerNames[spec.Type]; !ok {
+			defaultAuthorizerNames[spec.Type] = authorizer.LogicalName()
+		}
+	}
+
+	for _, path := range paths {
+		routeConfig := cfg.RouteConfigs[path.Path]
+
+		httpMethods := routeConfig.Methods
+		if len(httpMethods) == 0 {
+			httpMethods = []string{"ANY"}
+		}
+
+		authorizationType := routeConfig.Authorization
+		if authorizationType == "" {
+			authorizationType = string(AuthorizerTypeJWT)
+		}
+
+		authorizerLogicalName := routeConfig.AuthorizerName
+		if authorizerLogicalName == "" {
+			authorizerLogicalName = defaultAuthorizerNames[AuthorizerType(authorizationType)]
+		}
+		if authorizerLogicalName == "" && authorizationTypeRequiresAuthorizer(authorizationType) {
+			return nil, fmt.Errorf("path %q: authorization type %q requires an authorizer, but none was configured (add an AuthorizerSpec of the matching type or set RouteConfig.AuthorizerName)", path.Path, authorizationType)
+		}
+
+		rawPathName := path.Path
+		integrationLogicalName, err := registry.resolve("Integration" + rawPathName)
+		if err != nil {
+			return nil, err
+		}
+		template.Resources[integrationLogicalName] = buildAWSApiGatewayV2Integration(backendBaseURI, path.Path, routeConfig.RequestParameters)
+
+		for _, httpMethod := range httpMethods {
+			routeLogicalName, err := registry.resolve("Route" + rawPathName + strings.Title(strings.ToLower(httpMethod)))
+			if err != nil {
+				return nil, err
+			}
+			routeKey := toV2RouteKey(httpMethod, path.Path)
+			template.Resources[routeLogicalName] = buildAWSApiGatewayV2RouteResource(routeKey, integrationLogicalName, authorizationType, authorizerLogicalName)
+		}
+	}
+
+	template.Resources["TargetGroup"] = buildAWSElasticLoadBalancingV2TargetGroup(*cfg.Network.Vpc.VpcId, cfg.Network.InstanceIDs, cfg.NodePort, cfg.BackendCertificateArn, []string{"LoadBalancer"})
+	template.Resources["Listener"] = buildAWSElasticLoadBalancingV2Listener(cfg.BackendCertificateArn)
+
+	securityGroupIngresses := buildAWSEC2SecurityGroupIngresses(cfg.Network.SecurityGroupIDs, *cfg.Network.Vpc.CidrBlock, cfg.NodePort)
+	for i, sgI := range securityGroupIngresses {
+		template.Resources[fmt.Sprintf("SecurityGroupIngress%d", i)] = sgI
+	}
+
+	template.Resources["LoadBalancer"] = buildAWSElasticLoadBalancingV2LoadBalancer(cfg.Network.SubnetIDs)
+	template.Resources["VPCLink"] = buildAWSApiGatewayVpcLink([]string{"LoadBalancer"})
+	template.Resources["HttpStage"] = buildAWSApiGatewayV2HttpStage(cfg.StageName)
+
+	template.Outputs = map[string]interface{}{
+		OutputKeyRestApiID:          Output{Value: cfn.Ref("HttpApi")},
+		OutputKeyAPIGatewayEndpoint: Output{Value: cfn.Join("", []string{"https://", cfn.Ref("HttpApi"), ".execute-api.", cfn.Ref("AWS::Region"), ".amazonaws.com/", cfg.StageName})},
+		OutputKeyClientARNS:         Output{Value: strings.Join(cfg.Arns, ",")},
+	}
+
+	return template, nil
+}
+
+// BuildApiGatewayTemplateFromGateway is the Gateway API (sigs.k8s.io/gateway-api)
+// counterpart to BuildApiGatewayTemplateFromIngressRule: instead of an
+// extensionsv1beta1.Ingress, it takes a Gateway plus the HTTPRoutes and
+// TLSRoutes that attach to it, translates them into the same RouteConfig/path
+// shape the Ingress path already understands, and emits identical
+// CloudFormation resources. This lets users adopt the Gateway API CRDs in
+// place of the deprecated extensionsv1beta1.Ingress without a second
+// CloudFormation generator to maintain.
+func BuildApiGatewayTemplateFromGateway(gw *gatewayv1.Gateway, routes []*gatewayv1.HTTPRoute, tlsRoutes []*gatewayv1alpha2.TLSRoute, cfg *TemplateConfig) (*cfn.Template, error) {
+	gwCfg := *cfg
+	gwCfg.Rule = ingressRuleFromGateway(gw, routes, tlsRoutes, &gwCfg)
+	return BuildApiGatewayTemplateFromIngressRule(&gwCfg)
+}
+
+// ingressRuleFromGateway walks gw's HTTPRoutes/TLSRoutes and produces the
+// extensionsv1beta1.IngressRule BuildApiGatewayTemplateFromIngressRule
+// expects, populating cfg.RouteConfigs as a side effect so that per-match
+// method/header overrides survive the translation. Matches that share a path
+// become one path whose methods are the union of theirs (ANY if any of them
+// names no method); its RequestParameters are those of the last such match.
+func ingressRuleFromGateway(gw *gatewayv1.Gateway, routes []*gatewayv1.HTTPRoute, tlsRoutes []*gatewayv1alpha2.TLSRoute, cfg *TemplateConfig) extensionsv1beta1.IngressRule {
+	if cfg.RouteConfigs == nil {
+		cfg.RouteConfigs = map[string]RouteConfig{}
+	}
+
+	var host string
+	if len(gw.Spec.Listeners) > 0 && gw.Spec.Listeners[0].Hostname != nil {
+		host = string(*gw.Spec.Listeners[0].Hostname)
+	}
+
+	paths := []extensionsv1beta1.HTTPIngressPath{}
+	seen := map[string]bool{}
+	for _, route := range routes {
+		if !httpRouteAttachesToGateway(route, gw) {
+			continue
+		}
+
+		for _, hostname := range route.Spec.Hostnames {
+			if host == "" {
+				host = string(hostname)
+			}
+		}
+
+		for _, rule := range route.Spec.Rules {
+			for _, match := range rule.Matches {
+				path, routeConfig := httpRouteMatchToPathAndRouteConfig(match, rule.Filters)
+				if seen[path] {
+					prevMethods := cfg.RouteConfigs[path].Methods
+					if len(prevMethods) == 0 || len(routeConfig.Methods) == 0 {
+						routeConfig.Methods = nil
+					} else {
+						routeConfig.Methods = append(prevMethods, routeConfig.Methods...)
+					}
+				} else {
+					paths = append(paths, extensionsv1beta1.HTTPIngressPath{Path: path})
+				}
+				seen[path] = true
+				cfg.RouteConfigs[path] = routeConfig
+			}
+		}
+	}
+
+	for _, tlsRoute := range tlsRoutes {
+		if !tlsRouteAttachesToGateway(tlsRoute, gw) {
+			continue
+		}
+		if cfg.BackendSNIHostname == "" && len(tlsRoute.Spec.Hostnames) > 0 {
+			cfg.BackendSNIHostname = string(tlsRoute.Spec.Hostnames[0])
+		}
+	}
+
+	return extensionsv1beta1.IngressRule{
+		Host: host,
+		IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+			HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+				Paths: paths,
+			},
+		},
+	}
+}
+
+func httpRouteAttachesToGateway(route *gatewayv1.HTTPRoute, gw *gatewayv1.Gateway) bool {
+	for _, ref := range route.Spec.ParentRefs {
+		if parentRefMatchesGateway(string(ref.Name), ref.Namespace, ref.SectionName, gw) {
+			return true
+		}
+	}
+	return false
+}
+
+func tlsRouteAttachesToGateway(route *gatewayv1alpha2.TLSRoute, gw *gatewayv1.Gateway) bool {
+	for _, ref := range route.Spec.ParentRefs {
+		if parentRefMatchesGateway(string(ref.Name), ref.Namespace, ref.SectionName, gw) {
+			return true
+		}
+	}
+	return false
+}
+
+func parentRefMatchesGateway(refName string, refNamespace *gatewayv1.Namespace, refSectionName *gatewayv1.SectionName, gw *gatewayv1.Gateway) bool {
+	if refName != gw.Name {
+		return false
+	}
+	if refNamespace != nil && string(*refNamespace) != gw.Namespace {
+		return false
+	}
+	if refSectionName == nil {
+		return true
+	}
+	for _, listener := range gw.Spec.Listeners {
+		if listener.Name == *refSectionName {
+			return true
+		}
+	}
+	return false
+}
+
+// httpRouteMatchToPathAndRouteConfig translates a single HTTPRouteMatch (and
+// the Filters applying to its rule) into the path string and RouteConfig
+// BuildApiGatewayTemplateFromIngressRule already understands. The trailing
+// "{proxy+}" catch-all is NOT added here - mapApiGatewayMethodsAndResourcesFromPaths
+// appends one to every path it's given, so doing it here too would double it
+// up. PathPrefix matches just get their trailing slash trimmed so they join
+// cleanly with that catch-all. The Set entries of RequestHeaderModifier
+// filters are folded into RouteConfig.RequestParameters as append:header
+// mappings; their Add and Remove entries are not translated (RequestParameters
+// are only honored by the HTTP API/ApiTypeHTTP
+// path - REST API proxy integrations have no per-route parameter mapping);
+// RequestRedirect/URLRewrite have no CloudFormation equivalent in this
+// template today and are accepted but not yet translated. match.Headers is
+// also accepted but not yet translated - neither API Gateway REST nor HTTP
+// API routes can match on request headers, so header-based splits on an
+// otherwise-identical path collapse to the same path+method match.
+func httpRouteMatchToPathAndRouteConfig(match gatewayv1.HTTPRouteMatch, filters []gatewayv1.HTTPRouteFilter) (string, RouteConfig) {
+	path := "/"
+	if match.Path != nil && match.Path.Value != nil {
+		path = *match.Path.Value
+		if match.Path.Type != nil && *match.Path.Type == gatewayv1.PathMatchPathPrefix {
+			path = strings.TrimRight(path, "/")
+			if path == "" {
+				path = "/"
+			}
+		}
+	}
+
+	routeConfig := RouteConfig{}
+	if match.Method != nil {
+		routeConfig.Methods = []string{string(*match.Method)}
+	}
+
+	for _, filter := range filters {
+		if filter.Type != gatewayv1.HTTPRouteFilterRequestHeaderModifier || filter.RequestHeaderModifier == nil {
+			continue
+		}
+		for _, header := range filter.RequestHeaderModifier.Set {
+			if routeConfig.RequestParameters == nil {
+				routeConfig.RequestParameters = map[string]string{}
+			}
+			routeConfig.RequestParameters[fmt.Sprintf("append:header.%s", header.Name)] = fmt.Sprintf("'%s'", header.Value)
+		}
+	}
+
+	return path, routeConfig
 }
@@ -0,0 +1,503 @@
+package cloudformation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/awslabs/amazon-apigateway-ingress-controller/pkg/network"
+	cfn "github.com/awslabs/goformation/cloudformation"
+	"github.com/awslabs/goformation/cloudformation/resources"
+
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// newTestNetwork returns a minimal network.Network satisfying every field
+// the template builders read - just enough VPC/subnet/security-group/instance
+// data to exercise the CloudFormation generation without a real AWS account.
+func newTestNetwork() *network.Network {
+	return &network.Network{
+		Vpc: &ec2.Vpc{
+			VpcId:     aws.String("vpc-0123456789abcdef0"),
+			CidrBlock: aws.String("10.0.0.0/16"),
+		},
+		InstanceIDs:      []string{"i-0123456789abcdef0"},
+		SubnetIDs:        []string{"subnet-0123456789abcdef0"},
+		SecurityGroupIDs: []string{"sg-0123456789abcdef0"},
+	}
+}
+
+// newTestIngressRule returns an IngressRule with a single path, the shape
+// every happy-path template test starts from.
+func newTestIngressRule(path string) extensionsv1beta1.IngressRule {
+	return extensionsv1beta1.IngressRule{
+		IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+			HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+				Paths: []extensionsv1beta1.HTTPIngressPath{{Path: path}},
+			},
+		},
+	}
+}
+
+func TestSanitizeLogicalName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "hyphenated segment", in: "/foo-bar", want: "foobar"},
+		{name: "path parameter", in: "/users/{id}", want: "usersid"},
+		{name: "proxy at non-terminal position", in: "/{proxy+}/foo", want: "proxyfoo"},
+		{name: "already clean", in: "ResourceFoo", want: "ResourceFoo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeLogicalName(tt.in); got != tt.want {
+				t.Errorf("sanitizeLogicalName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogicalNameRegistryResolve(t *testing.T) {
+	t.Run("same raw key returns the same name", func(t *testing.T) {
+		r := newLogicalNameRegistry()
+
+		first, err := r.resolve("Resource/foo-bar")
+		if err != nil {
+			t.Fatalf("resolve() error = %v", err)
+		}
+
+		second, err := r.resolve("Resource/foo-bar")
+		if err != nil {
+			t.Fatalf("resolve() error = %v", err)
+		}
+
+		if first != second {
+			t.Errorf("resolve() = %q, then %q; want same name for the same raw key", first, second)
+		}
+	})
+
+	t.Run("hyphenated segments colliding with their unhyphenated form are disambiguated", func(t *testing.T) {
+		r := newLogicalNameRegistry()
+
+		fooBar, err := r.resolve("Resource/foo-bar")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/foo-bar", err)
+		}
+
+		fooBarUnhyphenated, err := r.resolve("Resource/foobar")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/foobar", err)
+		}
+
+		if fooBar == fooBarUnhyphenated {
+			t.Errorf("resolve(%q) and resolve(%q) both returned %q; want distinct logical names", "Resource/foo-bar", "Resource/foobar", fooBar)
+		}
+	})
+
+	t.Run("path parameters colliding with a literal segment are disambiguated", func(t *testing.T) {
+		r := newLogicalNameRegistry()
+
+		param, err := r.resolve("Resource/users/{id}")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/users/{id}", err)
+		}
+
+		literal, err := r.resolve("Resource/users/id")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/users/id", err)
+		}
+
+		if param == literal {
+			t.Errorf("resolve(%q) and resolve(%q) both returned %q; want distinct logical names", "Resource/users/{id}", "Resource/users/id", param)
+		}
+	})
+
+	t.Run("proxy at a non-terminal position is sanitized and disambiguated like any other segment", func(t *testing.T) {
+		r := newLogicalNameRegistry()
+
+		nested, err := r.resolve("Resource/{proxy+}/foo")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/{proxy+}/foo", err)
+		}
+
+		collidingLiteral, err := r.resolve("Resource/proxyfoo")
+		if err != nil {
+			t.Fatalf("resolve(%q) error = %v", "Resource/proxyfoo", err)
+		}
+
+		if nested == collidingLiteral {
+			t.Errorf("resolve(%q) and resolve(%q) both returned %q; want distinct logical names", "Resource/{proxy+}/foo", "Resource/proxyfoo", nested)
+		}
+	})
+
+	t.Run("logical ID longer than 255 characters is rejected", func(t *testing.T) {
+		r := newLogicalNameRegistry()
+
+		long := make([]byte, 256)
+		for i := range long {
+			long[i] = 'a'
+		}
+
+		if _, err := r.resolve(string(long)); err == nil {
+			t.Error("resolve() error = nil, want error for a 256 character logical ID")
+		}
+	})
+}
+
+func TestBuildHTTPApiGatewayTemplate(t *testing.T) {
+	cfg := &TemplateConfig{
+		Network:   newTestNetwork(),
+		Rule:      newTestIngressRule("/foo"),
+		NodePort:  30000,
+		StageName: "prod",
+		ApiType:   ApiTypeHTTP,
+		Authorizers: []AuthorizerSpec{
+			{Name: "JWTAuthorizer", Type: AuthorizerTypeJWT, Issuer: "https://issuer.example.com", Audience: []string{"my-api"}},
+		},
+	}
+
+	template, err := buildHTTPApiGatewayTemplate(cfg)
+	if err != nil {
+		t.Fatalf("buildHTTPApiGatewayTemplate() error = %v", err)
+	}
+
+	if _, ok := template.Resources["HttpApi"].(*resources.AWSApiGatewayV2Api); !ok {
+		t.Error("template.Resources[\"HttpApi\"] missing or wrong type")
+	}
+	if _, ok := template.Resources["JWTAuthorizer"].(*resources.AWSApiGatewayV2Authorizer); !ok {
+		t.Error("template.Resources[\"JWTAuthorizer\"] missing or wrong type")
+	}
+
+	var route *resources.AWSApiGatewayV2Route
+	for _, r := range template.Resources {
+		if v, ok := r.(*resources.AWSApiGatewayV2Route); ok {
+			route = v
+			break
+		}
+	}
+	if route == nil {
+		t.Fatal("template.Resources has no AWSApiGatewayV2Route")
+	}
+	if route.AuthorizationType != "JWT" {
+		t.Errorf("route.AuthorizationType = %q, want \"JWT\"", route.AuthorizationType)
+	}
+	if want := cfn.Ref("JWTAuthorizer"); route.AuthorizerId != want {
+		t.Errorf("route.AuthorizerId = %v, want %v", route.AuthorizerId, want)
+	}
+}
+
+func TestBuildApiGatewayTemplateFromIngressRuleAuthorizers(t *testing.T) {
+	t.Run("the default no-annotation, no-authorizers case errors rather than deploying an invalid method", func(t *testing.T) {
+		cfg := &TemplateConfig{
+			Network:   newTestNetwork(),
+			Rule:      newTestIngressRule("/foo"),
+			NodePort:  30000,
+			StageName: "prod",
+		}
+
+		if _, err := BuildApiGatewayTemplateFromIngressRule(cfg); err == nil {
+			t.Error("BuildApiGatewayTemplateFromIngressRule() error = nil, want error when no Cognito authorizer is configured for the default AuthorizationType")
+		}
+	})
+
+	t.Run("a configured Cognito authorizer resolves as the default for the default AuthorizationType", func(t *testing.T) {
+		cfg := &TemplateConfig{
+			Network:   newTestNetwork(),
+			Rule:      newTestIngressRule("/foo"),
+			NodePort:  30000,
+			StageName: "prod",
+			Authorizers: []AuthorizerSpec{
+				{Name: "CognitoAuthorizer", Type: AuthorizerTypeCognito, ProviderARNs: []string{"arn:aws:cognito-idp:us-east-1:123456789012:userpool/pool"}},
+			},
+		}
+
+		template, err := BuildApiGatewayTemplateFromIngressRule(cfg)
+		if err != nil {
+			t.Fatalf("BuildApiGatewayTemplateFromIngressRule() error = %v", err)
+		}
+
+		if _, ok := template.Resources["CognitoAuthorizer"].(*resources.AWSApiGatewayAuthorizer); !ok {
+			t.Fatal("template.Resources[\"CognitoAuthorizer\"] missing or wrong type")
+		}
+
+		var method *resources.AWSApiGatewayMethod
+		for _, r := range template.Resources {
+			if v, ok := r.(*resources.AWSApiGatewayMethod); ok {
+				method = v
+				break
+			}
+		}
+		if method == nil {
+			t.Fatal("template.Resources has no AWSApiGatewayMethod")
+		}
+		if method.AuthorizationType != "COGNITO_USER_POOLS" {
+			t.Errorf("method.AuthorizationType = %q, want \"COGNITO_USER_POOLS\"", method.AuthorizationType)
+		}
+		if want := cfn.Ref("CognitoAuthorizer"); method.AuthorizerId != want {
+			t.Errorf("method.AuthorizerId = %v, want %v", method.AuthorizerId, want)
+		}
+	})
+
+	t.Run("a CUSTOM route config resolves a Lambda authorizer keyed by its method-facing AuthorizationType", func(t *testing.T) {
+		cfg := &TemplateConfig{
+			Network:   newTestNetwork(),
+			Rule:      newTestIngressRule("/foo"),
+			NodePort:  30000,
+			StageName: "prod",
+			RouteConfigs: map[string]RouteConfig{
+				"/foo": {Authorization: "CUSTOM"},
+			},
+			Authorizers: []AuthorizerSpec{
+				{Name: "LambdaAuthorizer", Type: AuthorizerTypeLambdaToken, LambdaFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:authorize"},
+			},
+		}
+
+		template, err := BuildApiGatewayTemplateFromIngressRule(cfg)
+		if err != nil {
+			t.Fatalf("BuildApiGatewayTemplateFromIngressRule() error = %v", err)
+		}
+
+		var method *resources.AWSApiGatewayMethod
+		for _, r := range template.Resources {
+			if v, ok := r.(*resources.AWSApiGatewayMethod); ok {
+				method = v
+				break
+			}
+		}
+		if method == nil {
+			t.Fatal("template.Resources has no AWSApiGatewayMethod")
+		}
+		if method.AuthorizationType != "CUSTOM" {
+			t.Errorf("method.AuthorizationType = %q, want \"CUSTOM\"", method.AuthorizationType)
+		}
+		if want := cfn.Ref("LambdaAuthorizer"); method.AuthorizerId != want {
+			t.Errorf("method.AuthorizerId = %v, want %v", method.AuthorizerId, want)
+		}
+	})
+}
+
+func TestBuildAWSElasticLoadBalancingV2Listener(t *testing.T) {
+	t.Run("no backend certificate listens with plain TCP on 80", func(t *testing.T) {
+		l := buildAWSElasticLoadBalancingV2Listener("")
+
+		if l.Protocol != "TCP" || l.Port != 80 {
+			t.Errorf("Protocol/Port = %s/%d, want TCP/80", l.Protocol, l.Port)
+		}
+		if len(l.Certificates) != 0 {
+			t.Errorf("Certificates = %v, want none", l.Certificates)
+		}
+	})
+
+	t.Run("a backend certificate switches the listener to TLS on 443", func(t *testing.T) {
+		l := buildAWSElasticLoadBalancingV2Listener("arn:aws:acm:us-east-1:123456789012:certificate/backend")
+
+		if l.Protocol != "TLS" || l.Port != 443 {
+			t.Errorf("Protocol/Port = %s/%d, want TLS/443", l.Protocol, l.Port)
+		}
+		if len(l.Certificates) != 1 || l.Certificates[0].CertificateArn != "arn:aws:acm:us-east-1:123456789012:certificate/backend" {
+			t.Errorf("Certificates = %v, want the backend certificate ARN", l.Certificates)
+		}
+	})
+}
+
+func TestBuildBackendBaseURI(t *testing.T) {
+	t.Run("no backend certificate proxies plain http to the load balancer DNS name", func(t *testing.T) {
+		got := buildBackendBaseURI("", "")
+		want := cfn.Join("", []string{"http://", cfn.GetAtt("LoadBalancer", "DNSName")})
+		if got != want {
+			t.Errorf("buildBackendBaseURI() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("a backend certificate with no SNI hostname proxies https to the load balancer DNS name", func(t *testing.T) {
+		got := buildBackendBaseURI("arn:aws:acm:us-east-1:123456789012:certificate/backend", "")
+		want := cfn.Join("", []string{"https://", cfn.GetAtt("LoadBalancer", "DNSName")})
+		if got != want {
+			t.Errorf("buildBackendBaseURI() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("a backend certificate with an SNI hostname proxies https to that hostname instead", func(t *testing.T) {
+		got := buildBackendBaseURI("arn:aws:acm:us-east-1:123456789012:certificate/backend", "backend.internal")
+		want := cfn.Join("", []string{"https://", "backend.internal"})
+		if got != want {
+			t.Errorf("buildBackendBaseURI() = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestBuildAWSApiGatewayMethods(t *testing.T) {
+	t.Run("default route config with no matching authorizer errors instead of emitting an invalid method", func(t *testing.T) {
+		mctx := methodBuildContext{}
+
+		if _, err := buildAWSApiGatewayMethods("MethodFoo", "ResourceFoo", "/foo", RouteConfig{}, mctx); err == nil {
+			t.Error("buildAWSApiGatewayMethods() error = nil, want error when no authorizer resolves for AuthorizationType COGNITO_USER_POOLS")
+		}
+	})
+
+	t.Run("default route config resolves the default Cognito authorizer", func(t *testing.T) {
+		mctx := methodBuildContext{
+			defaultAuthorizerNames: map[string]string{"COGNITO_USER_POOLS": "CognitoAuthorizer"},
+		}
+
+		methods, err := buildAWSApiGatewayMethods("MethodFoo", "ResourceFoo", "/foo", RouteConfig{}, mctx)
+		if err != nil {
+			t.Fatalf("buildAWSApiGatewayMethods() error = %v", err)
+		}
+
+		method, ok := methods["MethodFoo"]
+		if !ok {
+			t.Fatalf("buildAWSApiGatewayMethods() = %v, want a method keyed \"MethodFoo\"", methods)
+		}
+		if method.HttpMethod != "ANY" {
+			t.Errorf("HttpMethod = %q, want \"ANY\"", method.HttpMethod)
+		}
+		if want := cfn.Ref("CognitoAuthorizer"); method.AuthorizerId != want {
+			t.Errorf("AuthorizerId = %v, want %v", method.AuthorizerId, want)
+		}
+	})
+
+	t.Run("an explicit OPTIONS method alongside CORS errors instead of one silently overwriting the other", func(t *testing.T) {
+		mctx := methodBuildContext{}
+		routeConfig := RouteConfig{
+			Methods:       []string{"GET", "OPTIONS"},
+			Authorization: "NONE",
+			CORS:          &CORSConfig{},
+		}
+
+		_, err := buildAWSApiGatewayMethods("MethodFoo", "ResourceFoo", "/foo", routeConfig, mctx)
+		if err == nil {
+			t.Fatal("buildAWSApiGatewayMethods() error = nil, want error for an explicit OPTIONS method colliding with the CORS preflight key")
+		}
+		if !strings.Contains(err.Error(), "OPTIONS") {
+			t.Errorf("buildAWSApiGatewayMethods() error = %v, want it to mention the OPTIONS/CORS collision", err)
+		}
+	})
+}
+
+func TestBuildApiGatewayTemplateFromGateway(t *testing.T) {
+	hostname := gatewayv1.Hostname("example.com")
+	pathType := gatewayv1.PathMatchPathPrefix
+	pathValue := "/foo"
+	httpMethod := gatewayv1.HTTPMethodGet
+
+	gw := &gatewayv1.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+		Spec: gatewayv1.GatewaySpec{
+			Listeners: []gatewayv1.Listener{{Name: "http", Hostname: &hostname}},
+		},
+	}
+
+	route := &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "route", Namespace: "default"},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{
+				ParentRefs: []gatewayv1.ParentReference{{Name: gatewayv1.ObjectName("gw")}},
+			},
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					Matches: []gatewayv1.HTTPRouteMatch{
+						{
+							Path:   &gatewayv1.HTTPPathMatch{Type: &pathType, Value: &pathValue},
+							Method: &httpMethod,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := &TemplateConfig{
+		Network:   newTestNetwork(),
+		NodePort:  30000,
+		StageName: "prod",
+		Authorizers: []AuthorizerSpec{
+			{Name: "CognitoAuthorizer", Type: AuthorizerTypeCognito, ProviderARNs: []string{"arn:aws:cognito-idp:us-east-1:123456789012:userpool/pool"}},
+		},
+	}
+
+	template, err := BuildApiGatewayTemplateFromGateway(gw, []*gatewayv1.HTTPRoute{route}, nil, cfg)
+	if err != nil {
+		t.Fatalf("BuildApiGatewayTemplateFromGateway() error = %v", err)
+	}
+
+	var gatewayMethod *resources.AWSApiGatewayMethod
+	for _, r := range template.Resources {
+		if v, ok := r.(*resources.AWSApiGatewayMethod); ok {
+			gatewayMethod = v
+			break
+		}
+	}
+	if gatewayMethod == nil {
+		t.Fatal("template.Resources has no AWSApiGatewayMethod for the attached HTTPRoute")
+	}
+	if gatewayMethod.HttpMethod != "GET" {
+		t.Errorf("method.HttpMethod = %q, want \"GET\"", gatewayMethod.HttpMethod)
+	}
+}
+
+func TestBuildApiGatewayTemplateFromIngressRuleAccessLoggingWAFAndUsagePlan(t *testing.T) {
+	cfg := &TemplateConfig{
+		Network:   newTestNetwork(),
+		Rule:      newTestIngressRule("/foo"),
+		NodePort:  30000,
+		StageName: "prod",
+		Authorizers: []AuthorizerSpec{
+			{Name: "CognitoAuthorizer", Type: AuthorizerTypeCognito, ProviderARNs: []string{"arn:aws:cognito-idp:us-east-1:123456789012:userpool/pool"}},
+		},
+		AccessLogging: &AccessLoggingConfig{RetentionInDays: 14},
+		WebACLArn:     "arn:aws:wafv2:us-east-1:123456789012:regional/webacl/my-acl/abc123",
+		UsagePlan: &UsagePlanConfig{
+			QuotaLimit:         1000,
+			QuotaPeriod:        "DAY",
+			ThrottleBurstLimit: 10,
+			ThrottleRateLimit:  5,
+		},
+	}
+
+	template, err := BuildApiGatewayTemplateFromIngressRule(cfg)
+	if err != nil {
+		t.Fatalf("BuildApiGatewayTemplateFromIngressRule() error = %v", err)
+	}
+
+	if _, ok := template.Resources["AccessLogGroup"].(*resources.AWSLogsLogGroup); !ok {
+		t.Error("template.Resources[\"AccessLogGroup\"] missing or wrong type")
+	}
+
+	stage, ok := template.Resources["Stage"].(*resources.AWSApiGatewayStage)
+	if !ok {
+		t.Fatal("template.Resources[\"Stage\"] missing or wrong type")
+	}
+	if stage.AccessLogSetting == nil {
+		t.Error("stage.AccessLogSetting = nil, want it set when AccessLogging is configured")
+	}
+
+	webACLAssociation, ok := template.Resources["WebACLAssociation"].(*resources.AWSWAFv2WebACLAssociation)
+	if !ok {
+		t.Fatal("template.Resources[\"WebACLAssociation\"] missing or wrong type")
+	}
+	if webACLAssociation.WebACLArn != cfg.WebACLArn {
+		t.Errorf("webACLAssociation.WebACLArn = %q, want %q", webACLAssociation.WebACLArn, cfg.WebACLArn)
+	}
+
+	usagePlan, ok := template.Resources["UsagePlan"].(*resources.AWSApiGatewayUsagePlan)
+	if !ok {
+		t.Fatal("template.Resources[\"UsagePlan\"] missing or wrong type")
+	}
+	if len(usagePlan.ApiStages) != 1 || usagePlan.ApiStages[0].Stage != "prod" {
+		t.Errorf("usagePlan.ApiStages = %v, want one stage named \"prod\"", usagePlan.ApiStages)
+	}
+
+	if _, ok := template.Resources["ApiKey"].(*resources.AWSApiGatewayApiKey); !ok {
+		t.Error("template.Resources[\"ApiKey\"] missing or wrong type")
+	}
+	if _, ok := template.Resources["UsagePlanKey"].(*resources.AWSApiGatewayUsagePlanKey); !ok {
+		t.Error("template.Resources[\"UsagePlanKey\"] missing or wrong type")
+	}
+}